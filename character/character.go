@@ -0,0 +1,191 @@
+// Package character describes a Pathfinder 1E character sheet as far as Sacred
+// Geometry needs it, stored as JSON.
+package character
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// KnowledgeEngineering is the skill whose ranks set the Sacred Geometry dice
+// pool, unless the character's Variant names another.
+const KnowledgeEngineering = "Knowledge (engineering)"
+
+// SacredGeometryRanks is the ranks in Knowledge (engineering) the standard
+// feat requires.
+const SacredGeometryRanks = 2
+
+// Character is a character sheet. Only the fields Sacred Geometry uses are
+// interpreted; the rest are kept so the file can serve as the one place the
+// character is described.
+type Character struct {
+	Name          string           `json:"name"`
+	Classes       []Class          `json:"classes,omitempty"`
+	AbilityScores map[string]int   `json:"ability_scores,omitempty"`
+	Feats         []string         `json:"feats,omitempty"`
+	Traits        []string         `json:"traits,omitempty"`
+	Skills        map[string]Skill `json:"skills,omitempty"`
+	Gear          []string         `json:"gear,omitempty"`
+	Variant       *Variant         `json:"variant,omitempty"`
+}
+
+// Variant describes a homebrew or house-ruled version of Sacred Geometry, such
+// as a "Sacred Harmonics" feat that rolls Perform instead. Empty fields keep
+// the standard feat; a renamed feat has no rank prerequisite unless MinRanks
+// sets one.
+type Variant struct {
+	Feat     string `json:"feat,omitempty"`
+	Skill    string `json:"skill,omitempty"`
+	DiceFrom string `json:"dice_from,omitempty"` // "ranks" or "bonus"
+	MinRanks int    `json:"min_ranks,omitempty"`
+}
+
+// Class is one class the character has levels in.
+type Class struct {
+	Name        string `json:"name"`
+	Level       int    `json:"level"`
+	CasterLevel int    `json:"caster_level,omitempty"`
+}
+
+// Skill holds the ranks in a skill and its total bonus.
+type Skill struct {
+	Ranks int `json:"ranks"`
+	Bonus int `json:"bonus,omitempty"`
+}
+
+// Feat returns the name of the character's version of the feat.
+func (c *Character) Feat() string {
+	if c.Variant != nil && c.Variant.Feat != "" {
+		return c.Variant.Feat
+	}
+	return "Sacred Geometry"
+}
+
+// Skill returns the skill that sets the character's dice pool.
+func (c *Character) Skill() string {
+	if c.Variant != nil && c.Variant.Skill != "" {
+		return c.Variant.Skill
+	}
+	return KnowledgeEngineering
+}
+
+// DiceFrom returns "bonus" if the character rolls one die per point of skill
+// bonus, and "ranks" otherwise.
+func (c *Character) DiceFrom() string {
+	if c.Variant != nil && c.Variant.DiceFrom != "" {
+		return c.Variant.DiceFrom
+	}
+	return "ranks"
+}
+
+// MinRanks returns the ranks in Skill the character's feat requires.
+func (c *Character) MinRanks() int {
+	if c.Variant != nil {
+		if c.Variant.MinRanks > 0 {
+			return c.Variant.MinRanks
+		}
+		if c.Variant.Feat != "" {
+			return 0
+		}
+	}
+	return SacredGeometryRanks
+}
+
+// Ranks returns the character's ranks in Skill.
+func (c *Character) Ranks() int {
+	return c.Skills[c.Skill()].Ranks
+}
+
+// Bonus returns the character's total bonus in Skill.
+func (c *Character) Bonus() int {
+	return c.Skills[c.Skill()].Bonus
+}
+
+// Validate reports the first problem that would make the sheet unusable.
+func (c *Character) Validate() error {
+	if c.Name == "" {
+		return errors.New("character has no name")
+	}
+	for _, class := range c.Classes {
+		if class.Name == "" || class.Level < 1 {
+			return fmt.Errorf("class %q needs a name and a level of at least 1", class.Name)
+		}
+		if class.CasterLevel < 0 {
+			return fmt.Errorf("class %q has a negative caster level", class.Name)
+		}
+	}
+	if c.Variant != nil && c.Variant.MinRanks < 0 {
+		return errors.New("variant min_ranks must not be negative")
+	}
+	if d := c.DiceFrom(); d != "ranks" && d != "bonus" {
+		return fmt.Errorf("variant dice_from %q must be ranks or bonus", d)
+	}
+	for name, skill := range c.Skills {
+		if skill.Ranks < 0 {
+			return fmt.Errorf("skill %q has negative ranks", name)
+		}
+	}
+	return nil
+}
+
+// Read decodes and validates a character sheet. Unknown fields are rejected
+// so that typos in hand-edited files are caught.
+func Read(r io.Reader) (*Character, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var c Character
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("reading character: %w", err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Write encodes c as indented JSON.
+func (c *Character) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
+
+// Load reads the character sheet at path.
+func Load(path string) (*Character, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	c, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Save writes c to path, creating its directory if needed.
+func (c *Character) Save(path string) error {
+	var buf bytes.Buffer
+	if err := c.Write(&buf); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// DefaultPath is where sg keeps the imported character sheet.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sacred_geometry", "character.json"), nil
+}
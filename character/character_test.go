@@ -0,0 +1,78 @@
+package character
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		sheet   string
+		wantErr string
+	}{
+		{"minimal", `{"name": "Aldric"}`, ""},
+		{"full", `{"name": "Aldric", "classes": [{"name": "Wizard", "level": 5, "caster_level": 5}],
+			"skills": {"Knowledge (engineering)": {"ranks": 5, "bonus": 12}},
+			"variant": {"dice_from": "bonus", "min_ranks": 3}}`, ""},
+		{"unknown field", `{"name": "Aldric", "rnaks": 5}`, "unknown field"},
+		{"no name", `{"skills": {}}`, "no name"},
+		{"class without level", `{"name": "Aldric", "classes": [{"name": "Wizard"}]}`, "level of at least 1"},
+		{"negative caster level", `{"name": "Aldric", "classes": [{"name": "Wizard", "level": 1, "caster_level": -1}]}`, "negative caster level"},
+		{"negative ranks", `{"name": "Aldric", "skills": {"Knowledge (engineering)": {"ranks": -1}}}`, "negative ranks"},
+		{"bad dice_from", `{"name": "Aldric", "variant": {"dice_from": "level"}}`, "must be ranks or bonus"},
+		{"negative min_ranks", `{"name": "Aldric", "variant": {"min_ranks": -1}}`, "min_ranks"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tc.sheet))
+			switch {
+			case tc.wantErr == "" && err != nil:
+				t.Errorf("Read: %v", err)
+			case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+				t.Errorf("Read: err = %v, want one containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestVariant(t *testing.T) {
+	skills := map[string]Skill{
+		KnowledgeEngineering: {Ranks: 5, Bonus: 12},
+		"Perform (sing)":     {Ranks: 7, Bonus: 15},
+	}
+	for _, tc := range []struct {
+		name                   string
+		variant                *Variant
+		feat, skill, from      string
+		ranks, bonus, minRanks int
+	}{
+		{"standard", nil, "Sacred Geometry", KnowledgeEngineering, "ranks", 5, 12, 2},
+		{"empty variant", &Variant{}, "Sacred Geometry", KnowledgeEngineering, "ranks", 5, 12, 2},
+		{"bonus only", &Variant{DiceFrom: "bonus"}, "Sacred Geometry", KnowledgeEngineering, "bonus", 5, 12, 2},
+		{"other skill", &Variant{Skill: "Perform (sing)"}, "Sacred Geometry", "Perform (sing)", "ranks", 7, 15, 2},
+		{"renamed feat", &Variant{Feat: "Sacred Harmonics", Skill: "Perform (sing)"}, "Sacred Harmonics", "Perform (sing)", "ranks", 7, 15, 0},
+		{"renamed feat with prerequisite", &Variant{Feat: "Sacred Harmonics", Skill: "Perform (sing)", MinRanks: 3}, "Sacred Harmonics", "Perform (sing)", "ranks", 7, 15, 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Character{Name: "Aldric", Skills: skills, Variant: tc.variant}
+			if got := c.Feat(); got != tc.feat {
+				t.Errorf("Feat() = %q, want %q", got, tc.feat)
+			}
+			if got := c.Skill(); got != tc.skill {
+				t.Errorf("Skill() = %q, want %q", got, tc.skill)
+			}
+			if got := c.DiceFrom(); got != tc.from {
+				t.Errorf("DiceFrom() = %q, want %q", got, tc.from)
+			}
+			if got := c.Ranks(); got != tc.ranks {
+				t.Errorf("Ranks() = %d, want %d", got, tc.ranks)
+			}
+			if got := c.Bonus(); got != tc.bonus {
+				t.Errorf("Bonus() = %d, want %d", got, tc.bonus)
+			}
+			if got := c.MinRanks(); got != tc.minRanks {
+				t.Errorf("MinRanks() = %d, want %d", got, tc.minRanks)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !(js && wasm)
+
+// Command sg solves the prime constants for the Pathfinder 1E Sacred Geometry feat.
+package main
+
+import "github.com/msbritt/sacred_geometry/internal/cli"
+
+func main() {
+	cli.Main()
+}
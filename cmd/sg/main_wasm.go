@@ -0,0 +1,66 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall/js"
+
+	"github.com/msbritt/sacred_geometry/solver"
+)
+
+// In the browser, sg exposes solve(dice, level) on the global object instead
+// of reading the command line, and stays alive so it can be called again.
+func main() {
+	js.Global().Set("solve", js.FuncOf(jsSolve))
+	select {}
+}
+
+// jsSolve returns {primes, results: [{prime, expression, found}], success},
+// or {error} when the arguments are not an array of d6 values and a level.
+func jsSolve(this js.Value, args []js.Value) any {
+	if len(args) != 2 || args[0].Type() != js.TypeObject || args[1].Type() != js.TypeNumber {
+		return jsError("usage: solve(dice, level)")
+	}
+	primes, err := solver.PrimeConstants(args[1].Int())
+	if err != nil {
+		return jsError(err.Error())
+	}
+	dice := make([]int, args[0].Length())
+	for i := range dice {
+		v := args[0].Index(i)
+		if v.Type() != js.TypeNumber || v.Int() < 1 || v.Int() > 6 {
+			return jsError(fmt.Sprintf("dice[%d] is not a d6 value", i))
+		}
+		dice[i] = v.Int()
+	}
+
+	results, err := solver.NewSolver().Solve(context.Background(), dice, primes)
+	if err != nil && !errors.Is(err, solver.ErrNoSolution) {
+		return jsError(err.Error())
+	}
+
+	jsPrimes := make([]any, len(primes))
+	for i, p := range primes {
+		jsPrimes[i] = p
+	}
+	jsResults := make([]any, len(results))
+	for i, result := range results {
+		jsResults[i] = map[string]any{
+			"prime":      result.Prime,
+			"expression": result.Expression,
+			"found":      result.Found,
+		}
+	}
+	return map[string]any{
+		"primes":  jsPrimes,
+		"results": jsResults,
+		"success": err == nil,
+	}
+}
+
+func jsError(msg string) any {
+	return map[string]any{"error": msg}
+}
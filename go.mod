@@ -0,0 +1,3 @@
+module github.com/msbritt/sacred_geometry
+
+go 1.21
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/msbritt/sacred_geometry/character"
+)
+
+// runAdvise finds the fewest Knowledge (engineering) ranks that reach a spell
+// level with the requested confidence, and returns the process exit code.
+func runAdvise(args []string) int {
+	fs := flag.NewFlagSet("advise", flag.ContinueOnError)
+	level := fs.Int("level", 0, "effective spell level, after metamagic")
+	confidence := fs.Float64("confidence", 95, "required chance of success, in percent")
+	maxRanks := fs.Int("max-ranks", 20, "give up above this many ranks")
+	trials := fs.Int("trials", 0, "estimate from this many simulated rolls instead of computing exactly")
+	seed := fs.Int64("seed", 0, "seed the simulated rolls (0 uses the current time)")
+	onFailure := fs.String("on-failure", "", "also show the expected cost of failed castings: action, slot or nothing")
+	logs := addLogFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sg advise --level N [flags]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if err := logs.setup(false); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	s, err := newSolver()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	if _, err := s.PrimeConstants(*level); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --level: %v\n", err)
+		return exitError
+	}
+	if *confidence <= 0 || *confidence > 100 {
+		fmt.Fprintln(os.Stderr, "--confidence must be above 0 and at most 100.")
+		return exitError
+	}
+	if *trials < 0 {
+		fmt.Fprintln(os.Stderr, "--trials must not be negative.")
+		return exitError
+	}
+	cost, ok := failureCosts[*onFailure]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Invalid --on-failure %q (want action, slot or nothing).\n", *onFailure)
+		return exitError
+	}
+	source, err := diceSource(*seed, false, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	for ranks := character.SacredGeometryRanks; ranks <= *maxRanks; ranks++ {
+		var p float64
+		if *trials > 0 {
+			p, err = s.Simulate(ctx, *level, ranks, *trials, source)
+		} else {
+			p, err = s.SuccessProbability(ctx, *level, ranks)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		fmt.Printf("    %2d ranks: %s%s\n", ranks, formatPercent(p), cost.describe(p))
+		if p*100 >= *confidence {
+			fmt.Printf("Spell level %d needs %d ranks in Knowledge (engineering) for a %g%% chance of success.\n", *level, ranks, *confidence)
+			return exitSuccess
+		}
+	}
+	fmt.Printf("Spell level %d does not reach a %g%% chance of success within %d ranks.\n", *level, *confidence, *maxRanks)
+	return exitFailure
+}
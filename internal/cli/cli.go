@@ -0,0 +1,277 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/msbritt/sacred_geometry/character"
+	"github.com/msbritt/sacred_geometry/solver"
+)
+
+// Set at build time with -ldflags "-X github.com/msbritt/sacred_geometry/internal/cli.version=...",
+// and likewise for commit and date.
+var (
+	version = "dev"
+	commit  = ""
+	date    = ""
+)
+
+func printVersion() {
+	v, c, d := version, commit, date
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if v == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			v = info.Main.Version
+		}
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if c == "" {
+					c = setting.Value
+				}
+			case "vcs.time":
+				if d == "" {
+					d = setting.Value
+				}
+			}
+		}
+	}
+	if c == "" {
+		c = "unknown"
+	}
+	if d == "" {
+		d = "unknown"
+	}
+	fmt.Printf("sg %s (commit %s, built %s)\n", v, c, d)
+}
+
+// setupLogging configures the default logger. With quiet set, only errors are
+// logged, so warnings don't break scripts that expect no output.
+func setupLogging(level, format string, quiet bool) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level %q", level)
+	}
+	if quiet && lvl < slog.LevelError {
+		lvl = slog.LevelError
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+	switch format {
+	case "text":
+		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, opts)))
+	case "json":
+		slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, opts)))
+	default:
+		return fmt.Errorf("invalid log format %q (want text or json)", format)
+	}
+	return nil
+}
+
+// logFlags are the logging flags every command accepts.
+type logFlags struct {
+	level, format *string
+}
+
+func addLogFlags(fs *flag.FlagSet) logFlags {
+	return logFlags{
+		level:  fs.String("log-level", "warn", "log level: debug, info, warn or error"),
+		format: fs.String("log-format", "text", "log format: text or json"),
+	}
+}
+
+// setup configures logging from the parsed flags; see setupLogging.
+func (f logFlags) setup(quiet bool) error {
+	return setupLogging(*f.level, *f.format, quiet)
+}
+
+func diceSource(seed int64, useCrypto bool, manual string) (solver.DiceSource, error) {
+	if manual != "" {
+		var dice solver.FixedDice
+		for _, field := range strings.Split(manual, ",") {
+			d, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil || d < 1 || d > 6 {
+				return nil, fmt.Errorf("invalid d6 value %q in --dice", field)
+			}
+			dice = append(dice, d)
+		}
+		return dice, nil
+	}
+	if useCrypto {
+		return solver.CryptoDice{}, nil
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return solver.NewRandDice(seed), nil
+}
+
+// checkPrerequisite warns when ranks in skill are fewer than the min feat
+// requires. Every command still rolls, so tables that waive the prerequisite
+// can play on.
+func checkPrerequisite(feat, skill string, ranks, min int) {
+	if ranks < min {
+		slog.Warn(feat+" requires more ranks", "skill", skill, "ranks", ranks, "required", min)
+	}
+}
+
+// Process exit codes, so sg can be scripted.
+const (
+	exitSuccess = 0
+	exitFailure = 1
+	exitError   = 2
+)
+
+// appendSessionLog writes one casting to the session log, as a JSON line when
+// the file ends in .jsonl and as a markdown section otherwise.
+func appendSessionLog(path string, entry solver.CastResult) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if filepath.Ext(path) == ".jsonl" {
+		return json.NewEncoder(f).Encode(entry)
+	}
+	return markdownRenderer{}.Render(f, entry)
+}
+
+// Main runs the sg command line and exits the process with one of the exit
+// codes above.
+func Main() {
+	if len(os.Args) == 2 && (os.Args[1] == "version" || os.Args[1] == "--version") {
+		printVersion()
+		return
+	}
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "probability":
+			os.Exit(runProbability(os.Args[2:]))
+		case "advise":
+			os.Exit(runAdvise(os.Args[2:]))
+		case "character":
+			os.Exit(runCharacter(os.Args[2:]))
+		case "party":
+			os.Exit(runParty(os.Args[2:]))
+		case "repl":
+			os.Exit(runRepl(os.Args[2:]))
+		}
+	}
+	quiet := flag.Bool("quiet", false, "print nothing; report the result through the exit code only")
+	logs := addLogFlags(flag.CommandLine)
+	output := flag.String("output", "text", "output format: text, json, markdown, html, foundry (chat message JSON) or roll20 (roll template)")
+	seed := flag.Int64("seed", 0, "seed the dice roller for reproducible rolls (0 uses the current time)")
+	cryptoDice := flag.Bool("crypto-dice", false, "roll with crypto/rand instead of math/rand")
+	manualDice := flag.String("dice", "", "comma-separated dice you rolled yourself, used instead of rolling")
+	characterPath := flag.String("character", "", "character sheet to read ranks from when <ranks> is omitted (default: the imported character)")
+	diceFrom := flag.String("dice-from", "", "with a character sheet, roll one die per rank (ranks) or per point of total skill bonus (bonus, a house rule); defaults to the sheet's variant, else ranks")
+	sessionLog := flag.String("log-session", "", "append each casting to this file (markdown, or JSON lines for .jsonl)")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: sg [flags] <spell_level> [<ranks>]")
+		fmt.Fprintln(flag.CommandLine.Output(), "       sg probability [flags]")
+		fmt.Fprintln(flag.CommandLine.Output(), "       sg advise --level N [flags]")
+		fmt.Fprintln(flag.CommandLine.Output(), "       sg character import <file> | export [file]")
+		fmt.Fprintln(flag.CommandLine.Output(), "       sg party [flags] <spell_level> <character.json>...")
+		fmt.Fprintln(flag.CommandLine.Output(), "       sg repl [flags]")
+		fmt.Fprintln(flag.CommandLine.Output(), "       sg version")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if err := logs.setup(*quiet); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitError)
+	}
+
+	var out io.Writer = os.Stdout
+	if *quiet {
+		out = io.Discard
+	}
+	if flag.NArg() < 1 || flag.NArg() > 2 {
+		if !*quiet {
+			flag.Usage()
+		}
+		os.Exit(exitError)
+	}
+	spellLevel, err1 := strconv.Atoi(flag.Arg(0))
+	var ranks, pool int
+	var err2 error
+	feat, skill, minRanks := "Sacred Geometry", character.KnowledgeEngineering, character.SacredGeometryRanks
+	if flag.NArg() == 2 {
+		if *diceFrom != "" {
+			fmt.Fprintln(os.Stderr, "--dice-from needs a character sheet; leave out <ranks> to use one.")
+			os.Exit(exitError)
+		}
+		ranks, err2 = strconv.Atoi(flag.Arg(1))
+		pool = ranks
+	} else {
+		c, err := loadCharacter(*characterPath)
+		if err == nil {
+			pool, err = dicePool(c, *diceFrom)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitError)
+		}
+		ranks, feat, skill, minRanks = c.Ranks(), c.Feat(), c.Skill(), c.MinRanks()
+	}
+	s, err := newSolver()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitError)
+	}
+	_, err = s.PrimeConstants(spellLevel)
+	if err1 != nil || err2 != nil || err != nil || ranks < 0 {
+		fmt.Fprintf(os.Stderr, "Please enter a valid spell level (1-%d) and number of ranks.\n", s.SpellLevels())
+		os.Exit(exitError)
+	}
+	renderer, ok := renderers[*output]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown output format %q.\n", *output)
+		os.Exit(exitError)
+	}
+	source, err := diceSource(*seed, *cryptoDice, *manualDice)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitError)
+	}
+	if fixed, ok := source.(solver.FixedDice); ok && len(fixed) != pool {
+		fmt.Fprintf(os.Stderr, "Got %d dice but expected %d.\n", len(fixed), pool)
+		os.Exit(exitError)
+	}
+	checkPrerequisite(feat, skill, ranks, minRanks)
+
+	engine := solver.NewEngine(source, s)
+	if *sessionLog != "" {
+		engine.OnResult(func(c solver.CastResult) {
+			if err := appendSessionLog(*sessionLog, c); err != nil {
+				slog.Error("could not write session log", "path", *sessionLog, "err", err)
+			}
+		})
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	c, err := engine.CastPool(ctx, spellLevel, ranks, pool)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitError)
+	}
+
+	if err := renderer.Render(out, c); err != nil {
+		slog.Error("could not write output", "err", err)
+	}
+
+	if !c.Success {
+		os.Exit(exitFailure)
+	}
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/msbritt/sacred_geometry/solver"
+)
+
+// config is the optional settings file in the user's config directory, next
+// to the imported character sheet.
+type config struct {
+	// PrimeConstants replaces the standard table for homebrew games; row i
+	// holds the primes for spell level i+1.
+	PrimeConstants [][]int `json:"prime_constants,omitempty"`
+}
+
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sacred_geometry", "config.json"), nil
+}
+
+// loadConfig reads the settings file. A missing file gives the defaults.
+func loadConfig() (config, error) {
+	var cfg config
+	path, err := configPath()
+	if err != nil {
+		return cfg, nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	} else if err != nil {
+		return cfg, err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if cfg.PrimeConstants != nil {
+		if err := solver.ValidatePrimeConstants(cfg.PrimeConstants); err != nil {
+			return cfg, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return cfg, nil
+}
+
+// newSolver returns a solver set up from the settings file.
+func newSolver() (*solver.Solver, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	var opts []solver.Option
+	if cfg.PrimeConstants != nil {
+		opts = append(opts, solver.WithPrimeConstants(cfg.PrimeConstants))
+	}
+	return solver.NewSolver(opts...), nil
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+
+	"github.com/msbritt/sacred_geometry/character"
+	"github.com/msbritt/sacred_geometry/solver"
+)
+
+// runParty rolls one spell level for several characters and prints the
+// results grouped by character, and returns the process exit code.
+func runParty(args []string) int {
+	fs := flag.NewFlagSet("party", flag.ContinueOnError)
+	quiet := fs.Bool("quiet", false, "print nothing; report the result through the exit code only")
+	seed := fs.Int64("seed", 0, "seed the dice roller for reproducible rolls (0 uses the current time)")
+	diceFrom := fs.String("dice-from", "", "roll one die per rank (ranks) or per point of total skill bonus (bonus, a house rule); defaults to the sheet's variant, else ranks")
+	logs := addLogFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sg party [flags] <spell_level> <character.json>...")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if err := logs.setup(*quiet); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	var out io.Writer = os.Stdout
+	if *quiet {
+		out = io.Discard
+	}
+	if fs.NArg() < 2 {
+		if !*quiet {
+			fs.Usage()
+		}
+		return exitError
+	}
+	s, err := newSolver()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	spellLevel, err := strconv.Atoi(fs.Arg(0))
+	if err == nil {
+		_, err = s.PrimeConstants(spellLevel)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Please enter a valid spell level (1-%d).\n", s.SpellLevels())
+		return exitError
+	}
+	// Load every sheet before rolling, so a typo in the last file doesn't
+	// waste the rolls for the others.
+	var party []*character.Character
+	var pools []int
+	for _, path := range fs.Args()[1:] {
+		c, err := character.Load(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		pool, err := dicePool(c, *diceFrom)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		party = append(party, c)
+		pools = append(pools, pool)
+	}
+	source, err := diceSource(*seed, false, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	engine := solver.NewEngine(source, s)
+
+	code := exitSuccess
+	for i, c := range party {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "== %s (%d ranks in %s) ==\n", c.Name, c.Ranks(), c.Skill())
+		checkPrerequisite(c.Feat(), c.Skill(), c.Ranks(), c.MinRanks())
+		result, err := engine.CastPool(ctx, spellLevel, c.Ranks(), pools[i])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		textRenderer{}.Render(out, result)
+		if !result.Success {
+			code = exitFailure
+		}
+	}
+	return code
+}
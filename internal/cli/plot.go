@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// levelColors are the line colors for spell levels 1 to 9; further levels
+// reuse them.
+var levelColors = []string{
+	"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd",
+	"#8c564b", "#e377c2", "#7f7f7f", "#bcbd22",
+}
+
+// writeSVGPlot draws one line per spell level of success chance against
+// ranks. grid[r][l] is the chance for minRanks+r ranks at level minLevel+l.
+func writeSVGPlot(w io.Writer, grid [][]float64, minRanks, minLevel int) error {
+	const (
+		width, height = 720, 420
+		left, right   = 60, 110
+		top, bottom   = 40, 50
+		plotW         = width - left - right
+		plotH         = height - top - bottom
+	)
+	span := len(grid) - 1
+	if span < 1 {
+		span = 1
+	}
+	x := func(r int) float64 {
+		if len(grid) == 1 {
+			return left + plotW/2
+		}
+		return left + float64(r)*plotW/float64(span)
+	}
+	y := func(p float64) float64 { return top + (1-p)*plotH }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n", width, height, width, height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+	fmt.Fprintf(&b, `<text x="%d" y="24" font-size="16">Sacred Geometry success chance by Knowledge (engineering) ranks</text>`+"\n", left)
+
+	for _, p := range []float64{0, 0.25, 0.5, 0.75, 1} {
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#ddd"/>`+"\n", left, y(p), left+plotW, y(p))
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end">%.0f%%</text>`+"\n", left-8, y(p)+4, p*100)
+	}
+	for r := range grid {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle">%d</text>`+"\n", x(r), top+plotH+18, minRanks+r)
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle">Ranks</text>`+"\n", left+plotW/2, height-10)
+
+	for l := range grid[0] {
+		color := levelColors[l%len(levelColors)]
+		var points []string
+		for r, row := range grid {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x(r), y(row[l])))
+		}
+		fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`+"\n", strings.Join(points, " "), color)
+		for r, row := range grid {
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"/>`+"\n", x(r), y(row[l]), color)
+		}
+		legendY := top + 10 + l*18
+		fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-width="2"/>`+"\n", left+plotW+15, legendY, left+plotW+35, legendY, color)
+		fmt.Fprintf(&b, `<text x="%d" y="%d">Level %d</text>`+"\n", left+plotW+40, legendY+4, minLevel+l)
+	}
+	b.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,248 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// runProbability prints a grid of success probabilities for a range of ranks
+// and spell levels, and returns the process exit code.
+func runProbability(args []string) int {
+	fs := flag.NewFlagSet("probability", flag.ContinueOnError)
+	ranksFlag := fs.String("ranks", "2-10", "Knowledge (engineering) ranks to show, as N or N-M")
+	levelsFlag := fs.String("levels", "", "spell levels to show, as N or N-M (default: every level in the prime constant table)")
+	trials := fs.Int("trials", 0, "estimate from this many simulated rolls instead of computing exactly")
+	seed := fs.Int64("seed", 0, "seed the simulated rolls (0 uses the current time)")
+	chart := fs.Bool("chart", false, "draw bar charts instead of a table")
+	chartBy := fs.String("by", "ranks", "with --chart, plot against ranks or level")
+	onFailure := fs.String("on-failure", "", "also show the expected cost of failed castings: action, slot or nothing")
+	plot := fs.String("plot", "", "also write an SVG line chart of the grid to this file")
+	logs := addLogFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sg probability [flags]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if err := logs.setup(false); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	minRanks, maxRanks, err := parseRange(*ranksFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --ranks: %v\n", err)
+		return exitError
+	}
+	s, err := newSolver()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	minLevel, maxLevel := 1, s.SpellLevels()
+	if *levelsFlag != "" {
+		minLevel, maxLevel, err = parseRange(*levelsFlag)
+	}
+	if err == nil {
+		if _, err = s.PrimeConstants(minLevel); err == nil {
+			_, err = s.PrimeConstants(maxLevel)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --levels: %v\n", err)
+		return exitError
+	}
+	if *trials < 0 {
+		fmt.Fprintln(os.Stderr, "--trials must not be negative.")
+		return exitError
+	}
+	if *chartBy != "ranks" && *chartBy != "level" {
+		fmt.Fprintf(os.Stderr, "Invalid --by %q (want ranks or level).\n", *chartBy)
+		return exitError
+	}
+	cost, ok := failureCosts[*onFailure]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Invalid --on-failure %q (want action, slot or nothing).\n", *onFailure)
+		return exitError
+	}
+	if *plot != "" && !strings.EqualFold(filepath.Ext(*plot), ".svg") {
+		fmt.Fprintln(os.Stderr, "--plot only writes SVG files; use a .svg file name.")
+		return exitError
+	}
+	source, err := diceSource(*seed, false, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// grid[r][l] is the chance for minRanks+r ranks at spell level minLevel+l.
+	var grid [][]float64
+	for ranks := minRanks; ranks <= maxRanks; ranks++ {
+		var row []float64
+		for level := minLevel; level <= maxLevel; level++ {
+			var p float64
+			if *trials > 0 {
+				p, err = s.Simulate(ctx, level, ranks, *trials, source)
+			} else {
+				p, err = s.SuccessProbability(ctx, level, ranks)
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return exitError
+			}
+			row = append(row, p)
+		}
+		grid = append(grid, row)
+	}
+
+	if *plot != "" {
+		f, err := os.Create(*plot)
+		if err == nil {
+			err = writeSVGPlot(f, grid, minRanks, minLevel)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not write %s: %v\n", *plot, err)
+			return exitError
+		}
+	}
+
+	if *trials > 0 {
+		fmt.Printf("Sacred Geometry success probability (%d simulated rolls each)\n", *trials)
+	} else {
+		fmt.Println("Sacred Geometry success probability (exact)")
+	}
+	// Columns are as wide as the widest header, which grows past level 9.
+	width := len(fmt.Sprintf("Level %d", maxLevel))
+	switch {
+	case !*chart:
+		fmt.Print("Ranks")
+		for level := minLevel; level <= maxLevel; level++ {
+			fmt.Printf("  %*s", width, fmt.Sprintf("Level %d", level))
+		}
+		fmt.Println()
+		for r, row := range grid {
+			fmt.Printf("%5d", minRanks+r)
+			for _, p := range row {
+				fmt.Printf("  %*s", width, formatPercent(p))
+			}
+			fmt.Println()
+		}
+		if cost.free {
+			fmt.Println("\nFailed castings cost nothing under these rules.")
+		} else if cost.unit != "" {
+			fmt.Printf("\nExpected %s lost per casting\n", cost.unit)
+			fmt.Print("Ranks")
+			for level := minLevel; level <= maxLevel; level++ {
+				fmt.Printf("  %*s", width, fmt.Sprintf("Level %d", level))
+			}
+			fmt.Println()
+			for r, row := range grid {
+				fmt.Printf("%5d", minRanks+r)
+				for _, p := range row {
+					fmt.Printf("  %*.2f", width, cost.expected(p))
+				}
+				fmt.Println()
+			}
+		}
+	case *chartBy == "level":
+		for r, row := range grid {
+			fmt.Printf("\n%d ranks\n", minRanks+r)
+			for l, p := range row {
+				fmt.Printf("    %-*s |%s %s%s\n", width, fmt.Sprintf("Level %d", minLevel+l), bar(p), formatPercent(p), cost.describe(p))
+			}
+		}
+	default:
+		for l := 0; l <= maxLevel-minLevel; l++ {
+			fmt.Printf("\nSpell level %d\n", minLevel+l)
+			for r, row := range grid {
+				fmt.Printf("    %2d ranks |%s %s%s\n", minRanks+r, bar(row[l]), formatPercent(row[l]), cost.describe(row[l]))
+			}
+		}
+	}
+	return exitSuccess
+}
+
+// bar draws p as a fixed-width ASCII bar.
+func bar(p float64) string {
+	const width = 40
+	filled := int(math.Round(p * width))
+	return strings.Repeat("#", filled) + strings.Repeat(" ", width-filled)
+}
+
+// failureCost is what a failed Sacred Geometry check costs under a table's
+// rules. Each failure loses one unit: the action, or the spell slot along with
+// it.
+type failureCost struct {
+	unit string // what a failure loses; empty when not reported
+	free bool   // failures lose nothing at all
+}
+
+// failureCosts maps --on-failure names to their cost model.
+var failureCosts = map[string]failureCost{
+	"":        {},
+	"nothing": {free: true},
+	"action":  {unit: "actions"},
+	"slot":    {unit: "spell slots"},
+}
+
+// expected returns the average loss per casting for a success chance p.
+func (c failureCost) expected(p float64) float64 {
+	if c.unit == "" {
+		return 0
+	}
+	return 1 - p
+}
+
+func (c failureCost) describe(p float64) string {
+	if c.free {
+		return " (failures cost nothing)"
+	}
+	if c.unit == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%.2f %s lost per casting)", c.expected(p), c.unit)
+}
+
+// parseRange parses "N" or "N-M" into an inclusive range of non-negative numbers.
+func parseRange(s string) (int, int, error) {
+	lo, hi, isRange := strings.Cut(s, "-")
+	min, err := strconv.Atoi(strings.TrimSpace(lo))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%q is not a number or range", s)
+	}
+	max := min
+	if isRange {
+		if max, err = strconv.Atoi(strings.TrimSpace(hi)); err != nil {
+			return 0, 0, fmt.Errorf("%q is not a number or range", s)
+		}
+	}
+	if min < 0 || max < min {
+		return 0, 0, fmt.Errorf("%q is not an increasing range", s)
+	}
+	return min, max, nil
+}
+
+// formatPercent keeps "0.0%" and "100.0%" for certainties, so rounding never
+// hides the rare rolls that fail.
+func formatPercent(p float64) string {
+	switch {
+	case p > 0 && p < 0.0005:
+		return "<0.1%"
+	case p < 1 && p >= 0.9995:
+		return ">99.9%"
+	}
+	return fmt.Sprintf("%.1f%%", p*100)
+}
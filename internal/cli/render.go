@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/msbritt/sacred_geometry/solver"
+)
+
+// Renderer writes a solver.CastResult in one output format.
+type Renderer interface {
+	Render(w io.Writer, c solver.CastResult) error
+}
+
+// renderers maps --output names to their Renderer.
+var renderers = map[string]Renderer{
+	"text":     textRenderer{},
+	"json":     jsonRenderer{},
+	"markdown": markdownRenderer{},
+	"html":     htmlRenderer{},
+	"foundry":  foundryRenderer{},
+	"roll20":   roll20Renderer{},
+}
+
+type textRenderer struct{}
+
+func (textRenderer) Render(w io.Writer, c solver.CastResult) error {
+	fmt.Fprintf(w, "    Prime constants for spell level %d: %v\n", c.SpellLevel, c.Primes)
+	fmt.Fprintf(w, "    Rolling %d d6 dice: %v\n", c.Pool, c.Dice)
+	for _, result := range c.Results {
+		if result.Found {
+			fmt.Fprintf(w, "    Combination to achieve prime %d: %s = %d\n", result.Prime, result.Expression, result.Prime)
+		} else {
+			fmt.Fprintf(w, "    No combination found to achieve prime %d\n", result.Prime)
+		}
+	}
+	var err error
+	if c.Success {
+		_, err = fmt.Fprintln(w, "Success: Combinations found for all prime constants.")
+	} else {
+		_, err = fmt.Fprintln(w, "Failure: Not all prime constants have combinations.")
+	}
+	return err
+}
+
+type jsonRenderer struct{}
+
+func (jsonRenderer) Render(w io.Writer, c solver.CastResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
+
+type markdownRenderer struct{}
+
+func (markdownRenderer) Render(w io.Writer, c solver.CastResult) error {
+	fmt.Fprintf(w, "## %s: spell level %d, %d ranks: %s\n\n", c.Time.Format("2006-01-02 15:04:05"), c.SpellLevel, c.Ranks, statusWord(c.Success))
+	fmt.Fprintf(w, "- Dice: %v\n", c.Dice)
+	for _, result := range c.Results {
+		if result.Found {
+			fmt.Fprintf(w, "- %d: `%s = %d`\n", result.Prime, result.Expression, result.Prime)
+		} else {
+			fmt.Fprintf(w, "- %d: no combination\n", result.Prime)
+		}
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+type htmlRenderer struct{}
+
+func (htmlRenderer) Render(w io.Writer, c solver.CastResult) error {
+	_, err := fmt.Fprintln(w, htmlContent(c))
+	return err
+}
+
+func htmlContent(c solver.CastResult) string {
+	var content strings.Builder
+	fmt.Fprintf(&content, "<p><strong>Dice (%dd6):</strong> %s</p><ul>", c.Pool, joinDice(c.Dice))
+	for _, result := range c.Results {
+		if result.Found {
+			fmt.Fprintf(&content, "<li>%d = %s</li>", result.Prime, html.EscapeString(result.Expression))
+		} else {
+			fmt.Fprintf(&content, "<li>%d: no combination</li>", result.Prime)
+		}
+	}
+	fmt.Fprintf(&content, "</ul><p><strong>%s</strong></p>", statusWord(c.Success))
+	return content.String()
+}
+
+// foundryRenderer writes a Foundry VTT chat message that can be passed to
+// ChatMessage.create.
+type foundryRenderer struct{}
+
+func (foundryRenderer) Render(w io.Writer, c solver.CastResult) error {
+	message := map[string]any{
+		"speaker": map[string]string{"alias": "Sacred Geometry"},
+		"flavor":  fmt.Sprintf("Sacred Geometry: spell level %d", c.SpellLevel),
+		"content": htmlContent(c),
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(message)
+}
+
+// roll20Renderer writes a default roll template; the dice are shown as text
+// rather than inline rolls so Roll20 does not reroll them.
+type roll20Renderer struct{}
+
+func (roll20Renderer) Render(w io.Writer, c solver.CastResult) error {
+	fmt.Fprintf(w, "&{template:default} {{name=Sacred Geometry (spell level %d)}} {{Dice (%dd6)=%s}}", c.SpellLevel, c.Pool, joinDice(c.Dice))
+	for _, result := range c.Results {
+		if result.Found {
+			fmt.Fprintf(w, " {{%d=%s}}", result.Prime, result.Expression)
+		} else {
+			fmt.Fprintf(w, " {{%d=no combination}}", result.Prime)
+		}
+	}
+	_, err := fmt.Fprintf(w, " {{Result=%s}}\n", statusWord(c.Success))
+	return err
+}
+
+func joinDice(dice []int) string {
+	values := make([]string, len(dice))
+	for i, d := range dice {
+		values[i] = strconv.Itoa(d)
+	}
+	return strings.Join(values, ", ")
+}
+
+func statusWord(success bool) string {
+	if success {
+		return "Success"
+	}
+	return "Failure"
+}
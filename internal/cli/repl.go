@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/msbritt/sacred_geometry/character"
+	"github.com/msbritt/sacred_geometry/solver"
+)
+
+const replHelp = `Commands:
+  roll <spell_level>   roll the feat with the current ranks
+  ranks [N]            show or set the ranks (and the dice, unless rolling by bonus)
+  prob <spell_level>   chance of success with the current ranks
+  history              list this session's rolls
+  help                 show this help
+  quit                 leave (also Ctrl-D)`
+
+// repl holds the state kept between commands in sg repl.
+type repl struct {
+	out      io.Writer
+	feat     string
+	skill    string
+	minRanks int
+	engine   *solver.Engine
+	solver   *solver.Solver
+	ranks    int
+	dice     int  // dice rolled; the ranks unless byBonus
+	byBonus  bool // dice come from the sheet's skill bonus
+	history  []solver.CastResult
+}
+
+// runRepl reads commands from standard input until quit or end of input, and
+// returns the process exit code.
+func runRepl(args []string) int {
+	fs := flag.NewFlagSet("repl", flag.ContinueOnError)
+	ranks := fs.Int("ranks", 0, "starting ranks in the feat's skill (default: from the character sheet)")
+	characterPath := fs.String("character", "", "character sheet to read starting ranks from (default: the imported character)")
+	diceFrom := fs.String("dice-from", "", "take the starting dice from the sheet's ranks (ranks) or total skill bonus (bonus, a house rule); defaults to the sheet's variant, else ranks")
+	seed := fs.Int64("seed", 0, "seed the dice roller for reproducible rolls (0 uses the current time)")
+	logs := addLogFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sg repl [flags]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if err := logs.setup(false); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return exitError
+	}
+	ranksSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "ranks" {
+			ranksSet = true
+		}
+	})
+	if ranksSet && *diceFrom != "" {
+		fmt.Fprintln(os.Stderr, "--dice-from needs a character sheet; leave out --ranks to use one.")
+		return exitError
+	}
+	// The sheet names the feat even when --ranks replaces its ranks.
+	feat, skill, minRanks := "Sacred Geometry", character.KnowledgeEngineering, character.SacredGeometryRanks
+	dice := *ranks
+	byBonus := false
+	c, err := loadCharacter(*characterPath)
+	if err != nil && !errors.Is(err, errNoCharacter) {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	if c != nil {
+		feat, skill, minRanks = c.Feat(), c.Skill(), c.MinRanks()
+	}
+	if c != nil && !ranksSet {
+		*ranks = c.Ranks()
+		if dice, err = dicePool(c, *diceFrom); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		byBonus = *diceFrom == "bonus" || (*diceFrom == "" && c.DiceFrom() == "bonus")
+	}
+	if *ranks < 0 {
+		fmt.Fprintln(os.Stderr, "--ranks must not be negative.")
+		return exitError
+	}
+	source, err := diceSource(*seed, false, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	s, err := newSolver()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	r := &repl{out: os.Stdout, feat: feat, skill: skill, minRanks: minRanks, engine: solver.NewEngine(source, s), solver: s, ranks: *ranks, dice: dice, byBonus: byBonus}
+	fmt.Fprintf(r.out, "%s with %s. Type help for commands.\n", r.feat, r.pool())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Fprint(r.out, "sg> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(r.out)
+			break
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "quit" || fields[0] == "exit" {
+			break
+		}
+		if err := r.exec(ctx, fields[0], fields[1:]); err != nil {
+			fmt.Fprintln(r.out, err)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	return exitSuccess
+}
+
+// exec runs one command. Errors are reported to the user and the session
+// carries on.
+func (r *repl) exec(ctx context.Context, cmd string, args []string) error {
+	switch cmd {
+	case "roll":
+		level, err := r.spellLevel(args)
+		if err != nil {
+			return err
+		}
+		checkPrerequisite(r.feat, r.skill, r.ranks, r.minRanks)
+		c, err := r.engine.CastPool(ctx, level, r.ranks, r.dice)
+		if err != nil {
+			return err
+		}
+		r.history = append(r.history, c)
+		return textRenderer{}.Render(r.out, c)
+	case "ranks":
+		if len(args) == 0 {
+			fmt.Fprintln(r.out, r.pool())
+			return nil
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 0 || len(args) > 1 {
+			return fmt.Errorf("usage: ranks N")
+		}
+		r.ranks = n
+		if !r.byBonus {
+			r.dice = n
+		}
+		fmt.Fprintln(r.out, r.pool())
+	case "prob":
+		level, err := r.spellLevel(args)
+		if err != nil {
+			return err
+		}
+		p, err := r.solver.SuccessProbability(ctx, level, r.dice)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(r.out, "Spell level %d with %s: %s\n", level, r.pool(), formatPercent(p))
+	case "history":
+		if len(r.history) == 0 {
+			fmt.Fprintln(r.out, "No rolls yet.")
+		}
+		for i, c := range r.history {
+			fmt.Fprintf(r.out, "%3d. level %d, %d ranks, dice %v: %s\n", i+1, c.SpellLevel, c.Ranks, c.Dice, statusWord(c.Success))
+		}
+	case "help":
+		fmt.Fprintln(r.out, replHelp)
+	default:
+		return fmt.Errorf("unknown command %q; type help for commands", cmd)
+	}
+	return nil
+}
+
+// pool describes the current ranks, and the dice when they differ.
+func (r *repl) pool() string {
+	if r.byBonus {
+		return fmt.Sprintf("%d ranks, rolling %d dice", r.ranks, r.dice)
+	}
+	return fmt.Sprintf("%d ranks", r.ranks)
+}
+
+func (r *repl) spellLevel(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("expected a spell level (1-%d)", r.solver.SpellLevels())
+	}
+	level, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid spell level %q", args[0])
+	}
+	if _, err := r.solver.PrimeConstants(level); err != nil {
+		return 0, err
+	}
+	return level, nil
+}
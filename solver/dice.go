@@ -0,0 +1,86 @@
+package solver
+
+import (
+	"crypto/rand"
+	"math/big"
+	mathrand "math/rand"
+	"sync"
+)
+
+// DiceSource rolls n dice with the given number of sides.
+type DiceSource interface {
+	Roll(n, sides int) []int
+}
+
+// RandDice rolls with math/rand; the same seed always gives the same rolls.
+type RandDice struct {
+	mu sync.Mutex
+	r  *mathrand.Rand
+}
+
+// NewRandDice returns a RandDice seeded with seed.
+func NewRandDice(seed int64) *RandDice {
+	return &RandDice{r: mathrand.New(mathrand.NewSource(seed))}
+}
+
+func (d *RandDice) Roll(n, sides int) []int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	dice := make([]int, n)
+	for i := range dice {
+		dice[i] = d.r.Intn(sides) + 1 // Generate a random number between 1 and sides (inclusive).
+	}
+	return dice
+}
+
+// CryptoDice rolls with crypto/rand.
+type CryptoDice struct{}
+
+func (CryptoDice) Roll(n, sides int) []int {
+	dice := make([]int, n)
+	for i := range dice {
+		v, err := rand.Int(rand.Reader, big.NewInt(int64(sides)))
+		if err != nil {
+			panic(err)
+		}
+		dice[i] = int(v.Int64()) + 1
+	}
+	return dice
+}
+
+// FixedDice returns the same values on every roll, such as dice rolled by
+// hand at the table. Rolls ask for at most len(FixedDice) dice.
+type FixedDice []int
+
+func (d FixedDice) Roll(n, sides int) []int {
+	if n > len(d) {
+		n = len(d)
+	}
+	dice := make([]int, n)
+	copy(dice, d)
+	return dice
+}
+
+// ReplayDice plays back previously recorded rolls in order, for example the
+// dice from a session log, and then starts again from the first roll.
+type ReplayDice struct {
+	mu    sync.Mutex
+	rolls [][]int
+	next  int
+}
+
+// NewReplayDice returns a ReplayDice that plays back rolls.
+func NewReplayDice(rolls [][]int) *ReplayDice {
+	return &ReplayDice{rolls: rolls}
+}
+
+func (d *ReplayDice) Roll(n, sides int) []int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.rolls) == 0 {
+		return nil
+	}
+	roll := d.rolls[d.next%len(d.rolls)]
+	d.next++
+	return FixedDice(roll).Roll(n, sides)
+}
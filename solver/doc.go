@@ -0,0 +1,8 @@
+// Package solver finds Sacred Geometry solutions: arithmetic expressions over
+// a roll of d6 dice that reach the prime constants for a spell level.
+//
+// Solver does the search, DiceSource supplies the dice and Engine combines the
+// two into a complete casting. The package follows semantic versioning: from
+// v1.0.0 on, exported identifiers are only removed in a new major version, and
+// are marked Deprecated for at least one minor release first.
+package solver
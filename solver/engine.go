@@ -0,0 +1,87 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// CastResult is one Sacred Geometry attempt: the dice rolled for a spell level
+// and the solution found for each prime constant. Ranks is the caster's ranks
+// in the feat's skill and Pool the number of dice rolled, which only differ
+// under house rules.
+type CastResult struct {
+	Time       time.Time  `json:"time"`
+	SpellLevel int        `json:"spell_level"`
+	Ranks      int        `json:"ranks"`
+	Pool       int        `json:"pool"`
+	Primes     []int      `json:"primes"`
+	Dice       []int      `json:"dice"`
+	Results    []Solution `json:"results"`
+	Success    bool       `json:"success"`
+}
+
+// Engine rolls the dice for a casting and solves its prime constants.
+type Engine struct {
+	dice   DiceSource
+	solver *Solver
+	hooks  []func(CastResult)
+}
+
+// NewEngine returns an Engine that rolls with dice and solves with s.
+func NewEngine(dice DiceSource, s *Solver) *Engine {
+	return &Engine{dice: dice, solver: s}
+}
+
+// OnResult registers fn to be called with every completed casting, in the
+// order the callbacks were registered.
+func (e *Engine) OnResult(fn func(CastResult)) {
+	e.hooks = append(e.hooks, fn)
+}
+
+// Cast rolls one d6 per rank and solves the prime constants for spellLevel.
+// A casting that fails to reach every prime is not an error; see
+// CastResult.Success.
+func (e *Engine) Cast(ctx context.Context, spellLevel, ranks int) (CastResult, error) {
+	return e.CastPool(ctx, spellLevel, ranks, ranks)
+}
+
+// CastPool is Cast for house rules that roll pool d6 instead of one per rank,
+// such as one die per point of skill bonus. It returns ErrInvalidDiceCount
+// when ranks or pool is negative, or the dice source rolls fewer than pool
+// dice.
+func (e *Engine) CastPool(ctx context.Context, spellLevel, ranks, pool int) (CastResult, error) {
+	primes, err := e.solver.PrimeConstants(spellLevel)
+	if err != nil {
+		return CastResult{}, err
+	}
+	if ranks < 0 || pool < 0 {
+		return CastResult{}, fmt.Errorf("%w: %d ranks and %d dice must not be negative", ErrInvalidDiceCount, ranks, pool)
+	}
+	dice := e.dice.Roll(pool, 6)
+	if len(dice) != pool {
+		return CastResult{}, fmt.Errorf("%w: rolled %d dice, want %d", ErrInvalidDiceCount, len(dice), pool)
+	}
+	slog.Debug("rolled dice", "spell_level", spellLevel, "primes", primes, "dice", dice)
+
+	results, err := e.solver.Solve(ctx, dice, primes)
+	if err != nil && !errors.Is(err, ErrNoSolution) {
+		return CastResult{}, err
+	}
+	result := CastResult{
+		Time:       time.Now(),
+		SpellLevel: spellLevel,
+		Ranks:      ranks,
+		Pool:       pool,
+		Primes:     primes,
+		Dice:       dice,
+		Results:    results,
+		Success:    err == nil,
+	}
+	for _, fn := range e.hooks {
+		fn(result)
+	}
+	return result, nil
+}
@@ -0,0 +1,35 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCastDiceCount(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name        string
+		dice        DiceSource
+		ranks, pool int
+	}{
+		{"negative ranks", NewRandDice(1), -1, -1},
+		{"negative pool", NewRandDice(1), 2, -1},
+		{"short roll", FixedDice{6, 6}, 5, 5},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewEngine(tc.dice, NewSolver()).CastPool(ctx, 1, tc.ranks, tc.pool)
+			if !errors.Is(err, ErrInvalidDiceCount) {
+				t.Errorf("CastPool(1, %d, %d): err = %v, want ErrInvalidDiceCount", tc.ranks, tc.pool, err)
+			}
+		})
+	}
+
+	c, err := NewEngine(FixedDice{3, 5, 2}, NewSolver()).CastPool(ctx, 1, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Ranks != 1 || c.Pool != 3 || len(c.Dice) != 3 || !c.Success {
+		t.Errorf("CastPool(1, 1, 3) = %+v", c)
+	}
+}
@@ -0,0 +1,157 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// SuccessProbability returns the exact chance that ranks d6 reach every prime
+// constant for spellLevel. Each distinct combination of dice is solved once
+// and weighted by how many rolls produce it. The weights are counted exactly
+// and divided once at the end, so a certain success or failure is exactly 1 or 0.
+func (s *Solver) SuccessProbability(ctx context.Context, spellLevel, ranks int) (float64, error) {
+	primes, err := s.PrimeConstants(spellLevel)
+	if err != nil {
+		return 0, err
+	}
+	if ranks < 0 {
+		return 0, fmt.Errorf("%w %d: ranks must not be negative", ErrInvalidDiceCount, ranks)
+	}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	type roll struct {
+		dice   []int
+		weight *big.Int
+	}
+	rolls := make(chan roll)
+	go func() {
+		defer close(rolls)
+		counts := make([]int, 6)
+		var fill func(face, left int)
+		fill = func(face, left int) {
+			if face == 5 {
+				counts[face] = left
+				var dice []int
+				for f, c := range counts {
+					for i := 0; i < c; i++ {
+						dice = append(dice, f+1)
+					}
+				}
+				select {
+				case rolls <- roll{dice: dice, weight: multinomial(counts)}:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for c := 0; c <= left && ctx.Err() == nil; c++ {
+				counts[face] = c
+				fill(face+1, left-c)
+			}
+		}
+		fill(0, ranks)
+	}()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	successes := new(big.Int)
+	for w := 0; w < s.workers(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range rolls {
+				if s.reachesAll(ctx, r.dice, primes) {
+					mu.Lock()
+					successes.Add(successes, r.weight)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("probability stopped: %w", err)
+	}
+	total := new(big.Int).Exp(big.NewInt(6), big.NewInt(int64(ranks)), nil)
+	probability, _ := new(big.Rat).SetFrac(successes, total).Float64()
+	return probability, nil
+}
+
+// Simulate estimates the chance that ranks dice from dice reach every prime
+// constant for spellLevel by rolling trials times.
+func (s *Solver) Simulate(ctx context.Context, spellLevel, ranks, trials int, dice DiceSource) (float64, error) {
+	primes, err := s.PrimeConstants(spellLevel)
+	if err != nil {
+		return 0, err
+	}
+	if ranks < 0 {
+		return 0, fmt.Errorf("%w %d: ranks must not be negative", ErrInvalidDiceCount, ranks)
+	}
+	if trials < 1 {
+		return 0, fmt.Errorf("%w %d: trials must be positive", ErrInvalidTrials, trials)
+	}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	// Rolls that differ only in order have the same outcome.
+	seen := map[string]bool{}
+	successes := 0
+	for i := 0; i < trials; i++ {
+		roll := dice.Roll(ranks, 6)
+		sort.Ints(roll)
+		key := fmt.Sprint(roll)
+		success, ok := seen[key]
+		if !ok {
+			success = s.reachesAll(ctx, roll, primes)
+			seen[key] = success
+		}
+		if ctx.Err() != nil {
+			return 0, fmt.Errorf("simulation stopped: %w", ctx.Err())
+		}
+		if success {
+			successes++
+		}
+	}
+	return float64(successes) / float64(trials), nil
+}
+
+func (s *Solver) reachesAll(ctx context.Context, dice []int, targets []int) bool {
+	sr := newSearch(ctx, s, dice)
+	for _, t := range targets {
+		if !sr.solvable(t) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Solver) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.timeout > 0 {
+		return context.WithTimeout(ctx, s.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+func (s *Solver) workers() int {
+	if s.parallelism > 0 {
+		return s.parallelism
+	}
+	return runtime.NumCPU()
+}
+
+// multinomial returns n! / (counts[0]! * counts[1]! * ...), where n is the
+// sum of counts: the number of ordered rolls with those counts of each face.
+func multinomial(counts []int) *big.Int {
+	result := big.NewInt(1)
+	n := 0
+	for _, c := range counts {
+		n += c
+		result.Mul(result, new(big.Int).Binomial(int64(n), int64(c)))
+	}
+	return result
+}
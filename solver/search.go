@@ -0,0 +1,299 @@
+package solver
+
+import (
+	"context"
+	"encoding/binary"
+	"sort"
+)
+
+// search answers whether a multiset of dice can be chained left to right into
+// a target. It works backwards from the target: the last die x and operator
+// determine which values the remaining dice must reach, and those answers are
+// memoized per remaining multiset, so equal dice and shared sub-problems are
+// only explored once instead of once per permutation.
+type search struct {
+	ctx     context.Context
+	solver  *Solver
+	values  []int                   // distinct dice values, ascending
+	counts  []int                   // how many of each value are in the roll
+	memo    map[string]map[int]bool // chains using exactly the dice in used
+	memoAny map[string]map[int]bool // chains using some of the dice in used
+	calls   int
+	stopped bool
+}
+
+func newSearch(ctx context.Context, s *Solver, dice []int) *search {
+	countOf := map[int]int{}
+	for _, d := range dice {
+		countOf[d]++
+	}
+	sr := &search{ctx: ctx, solver: s, memo: map[string]map[int]bool{}, memoAny: map[string]map[int]bool{}}
+	for v := range countOf {
+		sr.values = append(sr.values, v)
+	}
+	sort.Ints(sr.values)
+	for _, v := range sr.values {
+		sr.counts = append(sr.counts, countOf[v])
+	}
+	return sr
+}
+
+// find returns a formatted expression reaching target, preferring expressions
+// that use fewer dice.
+func (sr *search) find(target int) (string, bool) {
+	total := 0
+	for _, c := range sr.counts {
+		total += c
+	}
+	first := 1
+	if sr.solver.allDice {
+		first = total
+	}
+	used := make([]int, len(sr.counts))
+	for size := first; size <= total; size++ {
+		var nums []int
+		var ops []string
+		found := sr.eachSubset(used, 0, size, func() bool {
+			if !sr.reaches(used, size, target) {
+				return false
+			}
+			nums, ops = sr.chain(used, size, target)
+			return true
+		})
+		if sr.stopped {
+			return "", false
+		}
+		if found {
+			result, expr, ok := sr.solver.evalExpression(nums, ops)
+			return expr, ok && result == target
+		}
+	}
+	return "", false
+}
+
+// solvable reports whether target can be reached, without building the expression.
+func (sr *search) solvable(target int) bool {
+	used := append([]int(nil), sr.counts...)
+	if sr.solver.allDice {
+		total := 0
+		for _, c := range used {
+			total += c
+		}
+		return sr.reaches(used, total, target)
+	}
+	return sr.reachesAny(used, target)
+}
+
+// eachSubset fills used with every sub-multiset of the roll holding exactly
+// size dice, stopping as soon as fn returns true.
+func (sr *search) eachSubset(used []int, i, size int, fn func() bool) bool {
+	if i == len(used) {
+		return size == 0 && fn()
+	}
+	for c := 0; c <= sr.counts[i] && c <= size; c++ {
+		used[i] = c
+		if sr.eachSubset(used, i+1, size-c, fn) {
+			return true
+		}
+	}
+	used[i] = 0
+	return false
+}
+
+// reaches reports whether all size dice in used, in some order, chain into target.
+func (sr *search) reaches(used []int, size, target int) bool {
+	if sr.stopped {
+		return false
+	}
+	sr.calls++
+	if sr.calls%4096 == 0 && sr.ctx.Err() != nil {
+		sr.stopped = true
+		return false
+	}
+	if abs(target) > sr.bound(used) {
+		return false
+	}
+	if size == 1 {
+		for i, c := range used {
+			if c == 1 {
+				return sr.values[i] == target
+			}
+		}
+	}
+
+	key := memoKey(used)
+	if known, ok := sr.memo[key][target]; ok {
+		return known
+	}
+	found := false
+	for i, x := range sr.values {
+		if used[i] == 0 || found {
+			continue
+		}
+		used[i]--
+		for _, op := range sr.solver.operators {
+			lo, hi, ok := sr.solver.predecessors(op, target, x)
+			for v := lo; ok && v <= hi; v++ {
+				if sr.reaches(used, size-1, v) {
+					found = true
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+		used[i]++
+	}
+	if sr.stopped {
+		return false
+	}
+	if sr.memo[key] == nil {
+		sr.memo[key] = map[int]bool{}
+	}
+	sr.memo[key][target] = found
+	return found
+}
+
+// reachesAny reports whether some of the dice in used, in some order, chain
+// into target. Unlike reaches it needs no subset enumeration: the last die is
+// either the whole chain or appended to a chain of some of the remaining dice.
+func (sr *search) reachesAny(used []int, target int) bool {
+	if sr.stopped {
+		return false
+	}
+	sr.calls++
+	if sr.calls%4096 == 0 && sr.ctx.Err() != nil {
+		sr.stopped = true
+		return false
+	}
+	if abs(target) > sr.bound(used) {
+		return false
+	}
+	for i, c := range used {
+		if c > 0 && sr.values[i] == target {
+			return true
+		}
+	}
+
+	key := memoKey(used)
+	if known, ok := sr.memoAny[key][target]; ok {
+		return known
+	}
+	found := false
+	for i, x := range sr.values {
+		if used[i] == 0 || found {
+			continue
+		}
+		used[i]--
+		for _, op := range sr.solver.operators {
+			lo, hi, ok := sr.solver.predecessors(op, target, x)
+			for v := lo; ok && v <= hi; v++ {
+				if sr.reachesAny(used, v) {
+					found = true
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+		used[i]++
+	}
+	if sr.stopped {
+		return false
+	}
+	if sr.memoAny[key] == nil {
+		sr.memoAny[key] = map[int]bool{}
+	}
+	sr.memoAny[key][target] = found
+	return found
+}
+
+// chain rebuilds the numbers and operators of an expression that reaches
+// target; reaches(used, size, target) must be true.
+func (sr *search) chain(used []int, size, target int) ([]int, []string) {
+	if size == 1 {
+		return []int{target}, nil
+	}
+	for i, x := range sr.values {
+		if used[i] == 0 {
+			continue
+		}
+		used[i]--
+		for _, op := range sr.solver.operators {
+			lo, hi, ok := sr.solver.predecessors(op, target, x)
+			for v := lo; ok && v <= hi; v++ {
+				if sr.reaches(used, size-1, v) {
+					nums, ops := sr.chain(used, size-1, v)
+					used[i]++
+					return append(nums, x), append(ops, op)
+				}
+			}
+		}
+		used[i]++
+	}
+	return nil, nil
+}
+
+// bound is an upper limit on the absolute value any chain of the dice in
+// used can produce: each die x can at most multiply it by x+1.
+func (sr *search) bound(used []int) int {
+	const limit = 1 << 40
+	b := 1
+	for i, c := range used {
+		for j := 0; j < c; j++ {
+			b *= sr.values[i] + 1
+			if b > limit {
+				return limit
+			}
+		}
+	}
+	return b
+}
+
+// predecessors returns the range of values v for which v op x == target.
+func (s *Solver) predecessors(op string, target, x int) (lo, hi int, ok bool) {
+	switch op {
+	case "+":
+		return target - x, target - x, true
+	case "-":
+		return target + x, target + x, true
+	case "*":
+		if target%x != 0 {
+			return 0, 0, false
+		}
+		return target / x, target / x, true
+	case "/":
+		if !s.integerDivision {
+			return target * x, target * x, true
+		}
+		// Go division truncates toward zero, so several values divide down to target.
+		switch {
+		case target > 0:
+			return target * x, target*x + x - 1, true
+		case target < 0:
+			return target*x - x + 1, target * x, true
+		default:
+			return -(x - 1), x - 1, true
+		}
+	}
+	return 0, 0, false
+}
+
+// memoKey encodes the counts in used as varints, so any number of dice of a
+// face gets its own key.
+func memoKey(used []int) string {
+	key := make([]byte, 0, len(used))
+	for _, c := range used {
+		key = binary.AppendUvarint(key, uint64(c))
+	}
+	return string(key)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
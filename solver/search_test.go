@@ -0,0 +1,230 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// bruteForce returns, for every value some dice chain reaches, the fewest dice
+// that reach it, by trying every ordering of every subset with every operator.
+func bruteForce(s *Solver, dice []int) map[int]int {
+	reached := map[int]int{}
+	var nums []int
+	var ops []string
+	used := make([]bool, len(dice))
+	var extend func()
+	extend = func() {
+		if len(nums) > 0 && (!s.allDice || len(nums) == len(dice)) {
+			if v, _, ok := s.evalExpression(nums, ops); ok {
+				if n, seen := reached[v]; !seen || len(nums) < n {
+					reached[v] = len(nums)
+				}
+			}
+		}
+		for i, d := range dice {
+			if used[i] {
+				continue
+			}
+			used[i] = true
+			nums = append(nums, d)
+			if len(nums) == 1 {
+				extend()
+			} else {
+				for _, op := range s.operators {
+					ops = append(ops, op)
+					extend()
+					ops = ops[:len(ops)-1]
+				}
+			}
+			nums = nums[:len(nums)-1]
+			used[i] = false
+		}
+	}
+	extend()
+	return reached
+}
+
+// multisets returns every sorted roll of n d6.
+func multisets(n int) [][]int {
+	if n == 0 {
+		return [][]int{nil}
+	}
+	var rolls [][]int
+	for _, roll := range multisets(n - 1) {
+		first := 1
+		if len(roll) > 0 {
+			first = roll[len(roll)-1]
+		}
+		for d := first; d <= 6; d++ {
+			rolls = append(rolls, append(append([]int(nil), roll...), d))
+		}
+	}
+	return rolls
+}
+
+// expressionDice returns the numbers in an expression built by evalExpression.
+func expressionDice(t *testing.T, expr string) []int {
+	t.Helper()
+	var dice []int
+	for _, field := range strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(expr)) {
+		if strings.ContainsAny(field, "+-*/") {
+			continue
+		}
+		d, err := strconv.Atoi(field)
+		if err != nil {
+			t.Fatalf("expression %q: %v", expr, err)
+		}
+		dice = append(dice, d)
+	}
+	return dice
+}
+
+func TestSearchMatchesBruteForce(t *testing.T) {
+	solvers := map[string]*Solver{
+		"default":        NewSolver(),
+		"exact division": NewSolver(WithIntegerDivision(false)),
+		"all dice":       NewSolver(WithAllDice()),
+		"plus and times": NewSolver(WithOperators("+", "*")),
+	}
+	var targets []int
+	for t := -5; t <= 60; t++ {
+		targets = append(targets, t)
+	}
+	for name, s := range solvers {
+		t.Run(name, func(t *testing.T) {
+			for n := 1; n <= 4; n++ {
+				for _, dice := range multisets(n) {
+					want := bruteForce(s, dice)
+					solutions, err := s.Solve(context.Background(), dice, targets)
+					if err != nil && len(solutions) != len(targets) {
+						t.Fatalf("Solve(%v): %v", dice, err)
+					}
+					sr := newSearch(context.Background(), s, dice)
+					for _, sol := range solutions {
+						size, reachable := want[sol.Prime]
+						if sol.Found != reachable {
+							t.Errorf("Solve(%v) target %d: found = %v, want %v", dice, sol.Prime, sol.Found, reachable)
+							continue
+						}
+						if got := sr.solvable(sol.Prime); got != reachable {
+							t.Errorf("solvable(%v, %d) = %v, want %v", dice, sol.Prime, got, reachable)
+						}
+						if !sol.Found {
+							continue
+						}
+						if used := expressionDice(t, sol.Expression); len(used) != size {
+							t.Errorf("Solve(%v) target %d: %q uses %d dice, want %d", dice, sol.Prime, sol.Expression, len(used), size)
+						}
+					}
+				}
+			}
+		})
+	}
+
+	// Too many dice for the brute force, but n ones reach exactly -n+2..n.
+	t.Run("more than 255 of a face", func(t *testing.T) {
+		s := NewSolver()
+		dice := make([]int, 300)
+		for i := range dice {
+			dice[i] = 1
+		}
+		sr := newSearch(context.Background(), s, dice)
+		for _, tc := range []struct {
+			target int
+			want   bool
+		}{
+			{257, true}, {290, true}, {300, true}, {301, false},
+		} {
+			if got := sr.solvable(tc.target); got != tc.want {
+				t.Errorf("solvable(300 ones, %d) = %v, want %v", tc.target, got, tc.want)
+			}
+		}
+		solutions, _ := s.Solve(context.Background(), dice, []int{290})
+		if !solutions[0].Found {
+			t.Errorf("Solve(300 ones, 290): not found")
+		}
+	})
+}
+
+// bruteProbability counts the ordered rolls of n d6 that reach every target.
+func bruteProbability(s *Solver, n int, targets []int) (successes, total int) {
+	seen := map[string]bool{}
+	roll := make([]int, n)
+	var fill func(i int)
+	fill = func(i int) {
+		if i == n {
+			total++
+			sorted := append([]int(nil), roll...)
+			sort.Ints(sorted)
+			key := fmt.Sprint(sorted)
+			success, ok := seen[key]
+			if !ok {
+				success = true
+				reached := bruteForce(s, sorted)
+				for _, t := range targets {
+					if _, found := reached[t]; !found {
+						success = false
+					}
+				}
+				seen[key] = success
+			}
+			if success {
+				successes++
+			}
+			return
+		}
+		for d := 1; d <= 6; d++ {
+			roll[i] = d
+			fill(i + 1)
+		}
+	}
+	fill(0)
+	return successes, total
+}
+
+func TestSuccessProbability(t *testing.T) {
+	s := NewSolver()
+	for _, tc := range []struct {
+		level, ranks int
+	}{
+		{1, 1}, {1, 2}, {1, 3}, {2, 3}, {3, 4},
+	} {
+		t.Run(fmt.Sprintf("level %d with %d ranks", tc.level, tc.ranks), func(t *testing.T) {
+			primes, _ := s.PrimeConstants(tc.level)
+			successes, total := bruteProbability(s, tc.ranks, primes)
+			want := float64(successes) / float64(total)
+			got, err := s.SuccessProbability(context.Background(), tc.level, tc.ranks)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("SuccessProbability = %v, want %d/%d", got, successes, total)
+			}
+		})
+	}
+}
+
+func TestSuccessProbabilityCertain(t *testing.T) {
+	s := NewSolver()
+	// Every roll of 7 or more dice reaches 3, 5 and 7.
+	for ranks := 7; ranks <= 9; ranks++ {
+		got, err := s.SuccessProbability(context.Background(), 1, ranks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 1 {
+			t.Errorf("SuccessProbability(1, %d) = %v, want 1", ranks, got)
+		}
+	}
+	got, err := s.SuccessProbability(context.Background(), 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 0 {
+		t.Errorf("SuccessProbability(1, 0) = %v, want 0", got)
+	}
+}
@@ -0,0 +1,300 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+var primeConstants = [][]int{
+	{3, 5, 7},
+	{11, 13, 17},
+	{19, 23, 29},
+	{31, 37, 41},
+	{43, 47, 53},
+	{59, 61, 67},
+	{71, 73, 79},
+	{83, 89, 97},
+	{101, 103, 107},
+}
+
+var (
+	// ErrInvalidSpellLevel is returned for levels outside the prime constant table.
+	ErrInvalidSpellLevel = errors.New("invalid spell level")
+	// ErrInvalidPrimeConstants is returned by ValidatePrimeConstants.
+	ErrInvalidPrimeConstants = errors.New("invalid prime constant table")
+	// ErrInvalidDie is returned by Solve for dice below 1.
+	ErrInvalidDie = errors.New("invalid die")
+	// ErrInvalidDiceCount is returned for a negative number of dice, or when
+	// a DiceSource rolls fewer dice than asked.
+	ErrInvalidDiceCount = errors.New("invalid number of dice")
+	// ErrInvalidTrials is returned by Simulate for fewer than one trial.
+	ErrInvalidTrials = errors.New("invalid number of trials")
+	// ErrNoSolution is returned by Solve when at least one target could not
+	// be reached with the dice; the solutions are still returned.
+	ErrNoSolution = errors.New("no solution")
+)
+
+// OperatorError reports an operator the solver does not support.
+type OperatorError struct {
+	Op string
+}
+
+func (e *OperatorError) Error() string {
+	return fmt.Sprintf("unsupported operator %q", e.Op)
+}
+
+// PrimeConstants returns the three primes a spell of the given level must
+// reach, after metamagic is applied, from the standard table.
+func PrimeConstants(level int) ([]int, error) {
+	return primesFor(primeConstants, level)
+}
+
+func primesFor(table [][]int, level int) ([]int, error) {
+	if level < 1 || level > len(table) {
+		return nil, fmt.Errorf("%w %d: must be between 1 and %d", ErrInvalidSpellLevel, level, len(table))
+	}
+	// Return a copy so callers can't modify the table through the result.
+	return append([]int(nil), table[level-1]...), nil
+}
+
+// ValidatePrimeConstants checks a replacement prime constant table: it must
+// have at least one row, and every row must hold at least one prime and
+// nothing else.
+func ValidatePrimeConstants(table [][]int) error {
+	if len(table) == 0 {
+		return fmt.Errorf("%w: no spell levels", ErrInvalidPrimeConstants)
+	}
+	for i, row := range table {
+		if len(row) == 0 {
+			return fmt.Errorf("%w: spell level %d has no primes", ErrInvalidPrimeConstants, i+1)
+		}
+		for _, n := range row {
+			if !isPrime(n) {
+				return fmt.Errorf("%w: %d for spell level %d is not prime", ErrInvalidPrimeConstants, n, i+1)
+			}
+		}
+	}
+	return nil
+}
+
+func isPrime(n int) bool {
+	if n < 2 {
+		return false
+	}
+	for d := 2; d*d <= n; d++ {
+		if n%d == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Solver searches dice for arithmetic expressions that evaluate to target
+// numbers. Expressions are evaluated left to right, using each die at most once.
+// Dice must be positive.
+type Solver struct {
+	operators       []string
+	allDice         bool
+	integerDivision bool
+	timeout         time.Duration
+	parallelism     int
+	primes          [][]int
+}
+
+// Option configures a Solver.
+type Option func(*Solver)
+
+// WithOperators limits the operators the solver may use; the default is + - * /.
+func WithOperators(ops ...string) Option {
+	return func(s *Solver) {
+		s.operators = ops
+	}
+}
+
+// WithAllDice requires every die to be used in each expression.
+func WithAllDice() Option {
+	return func(s *Solver) {
+		s.allDice = true
+	}
+}
+
+// WithIntegerDivision controls whether division truncates (the default) or is
+// only allowed when it divides evenly.
+func WithIntegerDivision(truncate bool) Option {
+	return func(s *Solver) {
+		s.integerDivision = truncate
+	}
+}
+
+// WithTimeout stops the search after d, in addition to any deadline on the
+// context passed to Solve.
+func WithTimeout(d time.Duration) Option {
+	return func(s *Solver) {
+		s.timeout = d
+	}
+}
+
+// WithParallelism limits how many searches run at once. By default Solve
+// searches all targets concurrently and the probability methods use one
+// worker per CPU.
+func WithParallelism(n int) Option {
+	return func(s *Solver) {
+		s.parallelism = n
+	}
+}
+
+// WithPrimeConstants replaces the standard prime constant table, for homebrew
+// games with higher spell levels or other prime bands. Row i holds the primes
+// for spell level i+1. The table should pass ValidatePrimeConstants. The
+// solver keeps its own copy, so later changes to table have no effect.
+func WithPrimeConstants(table [][]int) Option {
+	primes := make([][]int, len(table))
+	for i, row := range table {
+		primes[i] = append([]int(nil), row...)
+	}
+	return func(s *Solver) {
+		s.primes = primes
+	}
+}
+
+// NewSolver returns a Solver that uses + - * / with truncating division,
+// searches any subset of the dice, has no timeout and uses the standard prime
+// constants.
+func NewSolver(opts ...Option) *Solver {
+	s := &Solver{
+		operators:       []string{"+", "-", "*", "/"},
+		integerDivision: true,
+		primes:          primeConstants,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// PrimeConstants returns the prime constants for level from the solver's
+// table, which is the standard one unless WithPrimeConstants replaced it.
+func (s *Solver) PrimeConstants(level int) ([]int, error) {
+	return primesFor(s.primes, level)
+}
+
+// SpellLevels returns the highest spell level in the solver's table.
+func (s *Solver) SpellLevels() int {
+	return len(s.primes)
+}
+
+// Solution is the expression found for one target, if any.
+type Solution struct {
+	Prime      int    `json:"prime"`
+	Expression string `json:"expression,omitempty"`
+	Found      bool   `json:"found"`
+}
+
+// Solve searches for every target and returns the solutions sorted by target.
+// If ctx is cancelled, targets not yet found are reported as not found and
+// the context's error is returned.
+func (s *Solver) Solve(ctx context.Context, dice []int, targets []int) ([]Solution, error) {
+	for _, op := range s.operators {
+		switch op {
+		case "+", "-", "*", "/":
+		default:
+			return nil, &OperatorError{Op: op}
+		}
+	}
+	for _, d := range dice {
+		if d < 1 {
+			return nil, fmt.Errorf("%w %d: dice must be positive", ErrInvalidDie, d)
+		}
+	}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	parallelism := s.parallelism
+	if parallelism <= 0 {
+		parallelism = len(targets)
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallelism)
+	solutionChan := make(chan Solution, len(targets))
+
+	for _, target := range targets {
+		wg.Add(1)
+		go func(t int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			start := time.Now()
+			expr, found := newSearch(ctx, s, dice).find(t)
+			slog.Debug("search finished", "prime", t, "found", found, "expression", expr, "elapsed", time.Since(start))
+			solutionChan <- Solution{Prime: t, Expression: expr, Found: found}
+		}(target)
+	}
+
+	wg.Wait()
+	close(solutionChan)
+
+	var solutions []Solution
+	for solution := range solutionChan {
+		solutions = append(solutions, solution)
+	}
+
+	sort.Slice(solutions, func(i, j int) bool {
+		return solutions[i].Prime < solutions[j].Prime
+	})
+
+	if err := ctx.Err(); err != nil {
+		return solutions, fmt.Errorf("search stopped: %w", err)
+	}
+	var missing []int
+	for _, solution := range solutions {
+		if !solution.Found {
+			missing = append(missing, solution.Prime)
+		}
+	}
+	if len(missing) > 0 {
+		return solutions, fmt.Errorf("%w for %v", ErrNoSolution, missing)
+	}
+	return solutions, nil
+}
+
+// evalExpression returns false when the expression is not allowed, such as
+// dividing by zero or an uneven division without integer division.
+func (s *Solver) evalExpression(nums []int, ops []string) (int, string, bool) {
+	if len(nums) == 0 {
+		return 0, "", false
+	}
+	result := nums[0]
+	expression := fmt.Sprintf("%d", nums[0])
+	for i := 1; i < len(nums); i++ {
+		nextNum := nums[i]
+		nextOp := ops[i-1]
+		previousOp := "+"
+		if i > 1 {
+			previousOp = ops[i-2]
+		}
+		if (previousOp == "+" || previousOp == "-") && (nextOp == "*" || nextOp == "/") {
+			expression = fmt.Sprintf("(%s) %s %d", expression, nextOp, nextNum)
+		} else {
+			expression = fmt.Sprintf("%s %s %d", expression, nextOp, nextNum)
+		}
+		switch nextOp {
+		case "+":
+			result += nextNum
+		case "-":
+			result -= nextNum
+		case "*":
+			result *= nextNum
+		case "/":
+			if nextNum == 0 || (!s.integerDivision && result%nextNum != 0) {
+				return 0, "", false
+			}
+			result /= nextNum
+		}
+	}
+	return result, expression, true
+}
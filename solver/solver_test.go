@@ -0,0 +1,42 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPrimeConstantsCopy(t *testing.T) {
+	primes, _ := PrimeConstants(1)
+	primes[0] = 4
+	if again, _ := PrimeConstants(1); again[0] != 3 {
+		t.Errorf("PrimeConstants(1) = %v after changing an earlier result", again)
+	}
+
+	table := [][]int{{2, 3}}
+	s := NewSolver(WithPrimeConstants(table))
+	table[0][0] = 4
+	if got, _ := s.PrimeConstants(1); got[0] != 2 {
+		t.Errorf("PrimeConstants(1) = %v after changing the table passed to WithPrimeConstants", got)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	s := NewSolver()
+	ctx := context.Background()
+	if _, err := s.Solve(ctx, []int{3, 0}, []int{3}); !errors.Is(err, ErrInvalidDie) {
+		t.Errorf("Solve with a 0 die: err = %v, want ErrInvalidDie", err)
+	}
+	if _, err := s.Simulate(ctx, 1, 3, 0, FixedDice{1, 2, 3}); !errors.Is(err, ErrInvalidTrials) {
+		t.Errorf("Simulate with 0 trials: err = %v, want ErrInvalidTrials", err)
+	}
+	if _, err := s.SuccessProbability(ctx, 1, -2); !errors.Is(err, ErrInvalidDiceCount) {
+		t.Errorf("SuccessProbability with -2 ranks: err = %v, want ErrInvalidDiceCount", err)
+	}
+	if _, err := s.Simulate(ctx, 1, -2, 10, NewRandDice(1)); !errors.Is(err, ErrInvalidDiceCount) {
+		t.Errorf("Simulate with -2 ranks: err = %v, want ErrInvalidDiceCount", err)
+	}
+	if _, err := s.SuccessProbability(ctx, 0, 3); !errors.Is(err, ErrInvalidSpellLevel) {
+		t.Errorf("SuccessProbability at level 0: err = %v, want ErrInvalidSpellLevel", err)
+	}
+}